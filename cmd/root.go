@@ -0,0 +1,130 @@
+// Package cmd implements tcping's command-line interface.
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/cloverstd/tcping/ping"
+	"github.com/cloverstd/tcping/ping/metrics"
+	"github.com/spf13/cobra"
+)
+
+var (
+	counter       int
+	interval      time.Duration
+	timeout       time.Duration
+	protocolFlag  string
+	metricsListen string
+	forceIPv4     bool
+	forceIPv6     bool
+	randomizeAddr bool
+	outputFormat  string
+)
+
+var rootCmd = &cobra.Command{
+	Use:   "tcping [protocol://]host[:port] [[protocol://]host[:port] ...]",
+	Short: "tcping is a tool to ping one or more hosts using TCP, HTTP(S) or ICMP",
+	Args:  cobra.MinimumNArgs(1),
+	RunE:  runRoot,
+}
+
+func init() {
+	flags := rootCmd.Flags()
+	flags.IntVarP(&counter, "counter", "c", 0, "ping counter, 0 means infinite")
+	flags.DurationVarP(&interval, "interval", "i", ping.DefaultInterval, "ping interval")
+	flags.DurationVarP(&timeout, "timeout", "t", 5*time.Second, "ping timeout")
+	flags.StringVarP(&protocolFlag, "protocol", "p", ping.TCP.String(), "protocol to use: tcp, http, https or icmp")
+	flags.StringVar(&metricsListen, "metrics-listen", "", "address to serve Prometheus metrics on (e.g. :9125); disabled when empty")
+	flags.BoolVarP(&forceIPv4, "ipv4", "4", false, "force IPv4 resolution and dialing")
+	flags.BoolVarP(&forceIPv6, "ipv6", "6", false, "force IPv6 resolution and dialing")
+	flags.BoolVar(&randomizeAddr, "randomize-address", false, "pick a random resolved address per probe instead of round-robining through them")
+	flags.StringVarP(&outputFormat, "output", "o", string(ping.OutputText), "output format: text, json or csv")
+}
+
+// Execute runs the root command.
+func Execute() error {
+	return rootCmd.Execute()
+}
+
+func runRoot(cmd *cobra.Command, args []string) error {
+	if forceIPv4 && forceIPv6 {
+		return fmt.Errorf("-4 and -6 are mutually exclusive")
+	}
+	network := ""
+	switch {
+	case forceIPv4:
+		network = "ip4"
+	case forceIPv6:
+		network = "ip6"
+	}
+
+	defaultProtocol, err := ping.NewProtocol(protocolFlag)
+	if err != nil {
+		return err
+	}
+
+	reporter, err := ping.NewReporter(ping.OutputFormat(outputFormat))
+	if err != nil {
+		return err
+	}
+
+	targets := make([]ping.ProbeTarget, 0, len(args))
+	for _, arg := range args {
+		targetURL, protocol, err := parseTarget(defaultProtocol, arg)
+		if err != nil {
+			return err
+		}
+		factory := ping.Load(protocol)
+		if factory == nil {
+			return fmt.Errorf("protocol %s is not registered", protocol)
+		}
+		prober, err := factory(targetURL, &ping.Option{Timeout: timeout, Network: network, RandomizeAddress: randomizeAddr})
+		if err != nil {
+			return err
+		}
+		targets = append(targets, ping.ProbeTarget{URL: targetURL, Ping: prober})
+	}
+
+	ctx, cancel := context.WithCancel(cmd.Context())
+	defer cancel()
+
+	if metricsListen != "" {
+		go func() {
+			if err := metrics.ListenAndServe(ctx, metricsListen); err != nil {
+				fmt.Fprintf(os.Stderr, "metrics server stopped: %s\n", err)
+			}
+		}()
+	}
+
+	pinger := ping.NewPinger(os.Stdout, reporter, interval, counter, targets...)
+	go func() {
+		<-ctx.Done()
+		pinger.Stop()
+	}()
+
+	pinger.Ping()
+	pinger.Summarize()
+	return nil
+}
+
+// parseTarget parses raw as a URL and determines which protocol to probe
+// it with: raw's own scheme when it has one (so each target in a
+// multi-target invocation can mix protocols, e.g.
+// "https://a.example icmp://b.example"), otherwise defaultProtocol. A
+// scheme tcping does not support is an error. A schemeless arg is
+// reparsed with defaultProtocol's scheme so it gains a host.
+func parseTarget(defaultProtocol ping.Protocol, raw string) (*url.URL, ping.Protocol, error) {
+	if u, err := url.Parse(raw); err == nil && u.Scheme != "" && u.Host != "" {
+		protocol, err := ping.NewProtocol(u.Scheme)
+		if err != nil {
+			return nil, 0, err
+		}
+		return u, protocol, nil
+	}
+	u, err := url.Parse(fmt.Sprintf("%s://%s", defaultProtocol, raw))
+	return u, defaultProtocol, err
+}
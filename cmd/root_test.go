@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/cloverstd/tcping/ping"
+)
+
+func TestParseTargetUsesOwnScheme(t *testing.T) {
+	u, protocol, err := parseTarget(ping.TCP, "https://a.example")
+	if err != nil {
+		t.Fatalf("parseTarget() error = %v", err)
+	}
+	if protocol != ping.HTTPS {
+		t.Errorf("protocol = %s, want %s", protocol, ping.HTTPS)
+	}
+	if u.String() != "https://a.example" {
+		t.Errorf("url = %s, want https://a.example", u)
+	}
+}
+
+func TestParseTargetFallsBackToDefaultProtocol(t *testing.T) {
+	u, protocol, err := parseTarget(ping.TCP, "b.example:22")
+	if err != nil {
+		t.Fatalf("parseTarget() error = %v", err)
+	}
+	if protocol != ping.TCP {
+		t.Errorf("protocol = %s, want %s", protocol, ping.TCP)
+	}
+	if u.String() != "tcp://b.example:22" {
+		t.Errorf("url = %s, want tcp://b.example:22", u)
+	}
+}
+
+func TestParseTargetUnrecognizedSchemeErrors(t *testing.T) {
+	if _, _, err := parseTarget(ping.TCP, "ftp://a.example"); err == nil {
+		t.Error("parseTarget() error = nil, want an error for an unsupported scheme")
+	}
+}
+
+func TestParseTargetMixedProtocols(t *testing.T) {
+	cases := []struct {
+		raw  string
+		want ping.Protocol
+	}{
+		{"https://a.example", ping.HTTPS},
+		{"icmp://b.example", ping.ICMP},
+		{"tcp://c.example:22", ping.TCP},
+		{"d.example:80", ping.TCP},
+	}
+	for _, c := range cases {
+		_, protocol, err := parseTarget(ping.TCP, c.raw)
+		if err != nil {
+			t.Fatalf("parseTarget(%q) error = %v", c.raw, err)
+		}
+		if protocol != c.want {
+			t.Errorf("parseTarget(%q) protocol = %s, want %s", c.raw, protocol, c.want)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package ping
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"time"
+)
+
+func init() {
+	Register(HTTP, newHTTPPing)
+	Register(HTTPS, newHTTPPing)
+}
+
+// httpDefaultTimeout is used when Option.Timeout is not set.
+const httpDefaultTimeout = 5 * time.Second
+
+type httpPing struct {
+	url *url.URL
+	op  *Option
+
+	// addrIndex round-robins across the resolved addresses of a
+	// multi-A/AAAA-record host, one position per probe.
+	addrIndex uint32
+
+	// transport is built once and reused across every probe so repeated
+	// Ping calls don't each leak a Transport's idle-conn goroutines and
+	// socket. Keep-alives are disabled on it so connect/TLS phases still
+	// fire on every probe.
+	transport *http.Transport
+}
+
+func newHTTPPing(u *url.URL, op *Option) (Ping, error) {
+	p := &httpPing{url: u, op: op}
+	p.transport = p.buildTransport()
+	return p, nil
+}
+
+// Ping issues one HTTP(S) request against p.url, attaching a
+// httptrace.ClientTrace to break the round trip down into DNS, TCP
+// connect, TLS handshake and time-to-first-byte phases, all surfaced in
+// Stats.Meta alongside the response status and size.
+func (p *httpPing) Ping(ctx context.Context) *Stats {
+	stats := &Stats{}
+
+	timeout := p.op.Timeout
+	if timeout <= 0 {
+		timeout = httpDefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var (
+		dnsStart, dnsDone         time.Time
+		connectStart, connectDone time.Time
+		tlsStart, tlsDone         time.Time
+		wroteRequest              time.Time
+		firstByte                 time.Time
+	)
+	trace := &httptrace.ClientTrace{
+		DNSStart:             func(httptrace.DNSStartInfo) { dnsStart = time.Now() },
+		DNSDone:              func(httptrace.DNSDoneInfo) { dnsDone = time.Now() },
+		ConnectStart:         func(string, string) { connectStart = time.Now() },
+		ConnectDone:          func(string, string, error) { connectDone = time.Now() },
+		TLSHandshakeStart:    func() { tlsStart = time.Now() },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { tlsDone = time.Now() },
+		WroteRequest:         func(httptrace.WroteRequestInfo) { wroteRequest = time.Now() },
+		GotFirstResponseByte: func() { firstByte = time.Now() },
+		GotConn: func(info httptrace.GotConnInfo) {
+			if info.Conn != nil {
+				stats.Address = info.Conn.RemoteAddr().String()
+			}
+		},
+	}
+	ctx = httptrace.WithClientTrace(ctx, trace)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url.String(), nil)
+	if err != nil {
+		stats.Error = err
+		return stats
+	}
+	if p.op.UA != "" {
+		req.Header.Set("User-Agent", p.op.UA)
+	}
+
+	client := &http.Client{Transport: p.transport}
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	stats.Duration = time.Since(start)
+	if !dnsStart.IsZero() && !dnsDone.IsZero() {
+		stats.DNSDuration = dnsDone.Sub(dnsStart)
+	}
+	if err != nil {
+		stats.Error = err
+		return stats
+	}
+	defer resp.Body.Close()
+
+	size, err := io.Copy(io.Discard, resp.Body)
+	if err != nil {
+		stats.Error = err
+		return stats
+	}
+	stats.Connected = true
+
+	meta := map[string]fmt.Stringer{
+		"status": intStringer(resp.StatusCode),
+		"size":   intStringer(size),
+	}
+	if !dnsStart.IsZero() && !dnsDone.IsZero() {
+		meta["dns"] = dnsDone.Sub(dnsStart)
+	}
+	if !connectStart.IsZero() && !connectDone.IsZero() {
+		meta["connect"] = connectDone.Sub(connectStart)
+	}
+	if !tlsStart.IsZero() && !tlsDone.IsZero() {
+		meta["tls"] = tlsDone.Sub(tlsStart)
+	}
+	if !wroteRequest.IsZero() && !firstByte.IsZero() {
+		meta["ttfb"] = firstByte.Sub(wroteRequest)
+	}
+	meta["total"] = stats.Duration
+	stats.Meta = meta
+
+	return stats
+}
+
+// buildTransport builds the http.Transport used for every probe: it
+// dials through the address family and proxy Option requests, and
+// resolves through Option.Resolver so multi-A/AAAA-record hosts are
+// rotated across like the other protocols. Keep-alives are disabled so
+// each probe still opens (and closes) its own connection instead of
+// idling one on the shared Transport forever.
+func (p *httpPing) buildTransport() *http.Transport {
+	dialer := &net.Dialer{Resolver: p.op.Resolver}
+	return &http.Transport{
+		Proxy:             func(*http.Request) (*url.URL, error) { return p.op.Proxy, nil },
+		DisableKeepAlives: true,
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			host, port, err := net.SplitHostPort(addr)
+			if err != nil {
+				return nil, err
+			}
+			ipAddr, err := p.resolve(ctx, host)
+			if err != nil {
+				return nil, err
+			}
+			return dialer.DialContext(ctx, network, net.JoinHostPort(ipAddr, port))
+		},
+	}
+}
+
+// resolve looks up host and returns one resolved address, rotating
+// through multiple A/AAAA records the same way the ICMP prober does.
+func (p *httpPing) resolve(ctx context.Context, host string) (string, error) {
+	if ip := net.ParseIP(host); ip != nil {
+		return host, nil
+	}
+
+	resolver := p.op.Resolver
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	lookupNetwork := p.op.Network
+	if lookupNetwork == "" {
+		lookupNetwork = "ip"
+	}
+	addrs, err := resolver.LookupIP(ctx, lookupNetwork, host)
+	if err != nil {
+		return "", err
+	}
+	return p.pickAddr(addrs).String(), nil
+}
+
+// pickAddr selects one of addrs for this probe: round-robin by default,
+// or uniformly at random when Option.RandomizeAddress is set.
+func (p *httpPing) pickAddr(addrs []net.IP) net.IP {
+	return pickAddr(addrs, &p.addrIndex, p.op.RandomizeAddress)
+}
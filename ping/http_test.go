@@ -0,0 +1,87 @@
+package ping
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestHTTPPingPopulatesMeta(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+		_, _ = w.Write([]byte("hello"))
+	}))
+	defer server.Close()
+
+	target, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("url.Parse() error = %v", err)
+	}
+	// Use a hostname instead of httptest's literal 127.0.0.1 address so
+	// the prober actually exercises its DNS phase.
+	target.Host = "localhost:" + target.Port()
+
+	prober, err := newHTTPPing(target, &Option{Timeout: 2 * time.Second})
+	if err != nil {
+		t.Fatalf("newHTTPPing() error = %v", err)
+	}
+
+	stats := prober.Ping(context.Background())
+	if stats.Error != nil {
+		t.Fatalf("Ping() error = %v", stats.Error)
+	}
+	if !stats.Connected {
+		t.Fatal("Ping() Connected = false, want true")
+	}
+
+	for _, key := range []string{"dns", "connect", "ttfb", "status", "size", "total"} {
+		if _, ok := stats.Meta[key]; !ok {
+			t.Errorf("Meta missing key %q, got %v", key, stats.Meta)
+		}
+	}
+	if got := stats.Meta["status"].String(); got != "418" {
+		t.Errorf("Meta[status] = %q, want %q", got, "418")
+	}
+	if got := stats.Meta["size"].String(); got != "5" {
+		t.Errorf("Meta[size] = %q, want %q", got, "5")
+	}
+}
+
+// TestHTTPPingReusesTransport repeatedly probes the same httpPing and
+// checks the goroutine count stays flat, guarding against a Transport
+// (and its idle-conn goroutines) being recreated on every Ping call.
+func TestHTTPPingReusesTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	target, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("url.Parse() error = %v", err)
+	}
+
+	prober, err := newHTTPPing(target, &Option{Timeout: 2 * time.Second})
+	if err != nil {
+		t.Fatalf("newHTTPPing() error = %v", err)
+	}
+
+	for i := 0; i < 50; i++ {
+		if stats := prober.Ping(context.Background()); stats.Error != nil {
+			t.Fatalf("Ping() #%d error = %v", i, stats.Error)
+		}
+	}
+
+	// Give any leaked connections' goroutines a moment to start before
+	// counting, then make sure probing didn't leave a goroutine behind
+	// per call.
+	time.Sleep(50 * time.Millisecond)
+	runtime.GC()
+	if n := runtime.NumGoroutine(); n > 20 {
+		t.Errorf("NumGoroutine() = %d after 50 probes, want a bounded count (transport leaking per-probe goroutines?)", n)
+	}
+}
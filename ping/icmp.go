@@ -0,0 +1,284 @@
+package ping
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+func init() {
+	Register(ICMP, newICMPPing)
+}
+
+// icmpDefaultTimeout is used when Option.Timeout is not set.
+const icmpDefaultTimeout = 5 * time.Second
+
+// protocol numbers from RFC 792 and RFC 4443, used to pick the ICMP
+// message parser for ipv4 versus ipv6 replies.
+const (
+	protocolICMP     = 1
+	protocolIPv6ICMP = 58
+)
+
+// icmpSeq is shared across every ICMP probe in the process so that echo
+// requests, including ones sent to different targets, carry increasing
+// sequence numbers (wrapping around after 0xffff).
+var icmpSeq uint32
+
+type icmpPing struct {
+	url *url.URL
+	op  *Option
+	id  int
+
+	// addrIndex round-robins across the resolved addresses of a
+	// multi-A/AAAA-record host, one position per probe.
+	addrIndex uint32
+}
+
+func newICMPPing(u *url.URL, op *Option) (Ping, error) {
+	return &icmpPing{
+		url: u,
+		op:  op,
+		id:  os.Getpid() & 0xffff,
+	}, nil
+}
+
+func (p *icmpPing) Ping(ctx context.Context) *Stats {
+	stats := &Stats{}
+
+	timeout := p.op.Timeout
+	if timeout <= 0 {
+		timeout = icmpDefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	resolver := p.op.Resolver
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+
+	dnsStart := time.Now()
+	addrs, err := resolveICMPAddrs(ctx, resolver, p.op.Network, p.url.Hostname())
+	stats.DNSDuration = time.Since(dnsStart)
+	if err != nil {
+		stats.Error = err
+		return stats
+	}
+	ipAddr := p.pickAddr(addrs)
+	isIPv4 := ipAddr.IP.To4() != nil
+	stats.Address = ipAddr.String()
+
+	conn, proto, err := listenICMP(isIPv4)
+	if err != nil {
+		stats.Error = err
+		return stats
+	}
+	defer conn.Close()
+
+	seq := int(atomic.AddUint32(&icmpSeq, 1)) & 0xffff
+	echoBody := &icmp.Echo{ID: p.id, Seq: seq, Data: []byte("tcping")}
+	wb, err := (&icmp.Message{Type: echoRequestType(isIPv4), Code: 0, Body: echoBody}).Marshal(nil)
+	if err != nil {
+		stats.Error = err
+		return stats
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	// Unprivileged datagram endpoints take a *net.UDPAddr destination, and
+	// the kernel may rewrite the echo ID on them, so their replies are
+	// matched by type and peer only.
+	_, datagram := conn.LocalAddr().(*net.UDPAddr)
+	var dst net.Addr = &net.IPAddr{IP: ipAddr.IP}
+	if datagram {
+		dst = &net.UDPAddr{IP: ipAddr.IP, Zone: ipAddr.Zone}
+	}
+
+	start := time.Now()
+	if _, err := conn.WriteTo(wb, dst); err != nil {
+		stats.Error = err
+		return stats
+	}
+
+	rb := make([]byte, 1500)
+	for {
+		n, peer, ttl, err := readICMP(conn, isIPv4, rb)
+		stats.Duration = time.Since(start)
+		if err != nil {
+			stats.Error = err
+			return stats
+		}
+		reply, err := icmp.ParseMessage(proto, rb[:n])
+		if err != nil {
+			stats.Error = err
+			return stats
+		}
+		if !isEchoReply(reply.Type, isIPv4) || !peerIP(peer).Equal(ipAddr.IP) {
+			continue
+		}
+		echo, ok := reply.Body.(*icmp.Echo)
+		if !ok || (!datagram && echo.ID != p.id) {
+			continue
+		}
+		stats.Connected = true
+		stats.Meta = map[string]fmt.Stringer{
+			"ttl":     intStringer(ttl),
+			"seq":     intStringer(echo.Seq),
+			"address": stringStringer(ipAddr.String()),
+		}
+		return stats
+	}
+}
+
+// pickAddr selects one of addrs for this probe: round-robin by default,
+// or uniformly at random when Option.RandomizeAddress is set. Either way,
+// successive probes against a multi-A/AAAA-record host exercise all of
+// its resolved endpoints.
+func (p *icmpPing) pickAddr(addrs []net.IPAddr) net.IPAddr {
+	return pickAddr(addrs, &p.addrIndex, p.op.RandomizeAddress)
+}
+
+// resolveICMPAddrs resolves host to every address matching the requested
+// network ("ip4"/"ip6"/"").
+func resolveICMPAddrs(ctx context.Context, resolver *net.Resolver, network, host string) ([]net.IPAddr, error) {
+	if ip := net.ParseIP(host); ip != nil {
+		return []net.IPAddr{{IP: ip}}, nil
+	}
+
+	lookupNetwork := network
+	if lookupNetwork == "" {
+		lookupNetwork = "ip"
+	}
+	resolved, err := resolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	addrs := make([]net.IPAddr, 0, len(resolved))
+	for _, addr := range resolved {
+		isIPv4 := addr.IP.To4() != nil
+		switch lookupNetwork {
+		case "ip4":
+			if isIPv4 {
+				addrs = append(addrs, addr)
+			}
+		case "ip6":
+			if !isIPv4 {
+				addrs = append(addrs, addr)
+			}
+		default:
+			addrs = append(addrs, addr)
+		}
+	}
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("no %s address found for %s", lookupNetwork, host)
+	}
+	return addrs, nil
+}
+
+// listenICMP opens a raw ICMP socket, falling back to an unprivileged
+// ICMP datagram socket (Linux/macOS "ping sockets") when the process does
+// not have permission to open a raw one.
+func listenICMP(isIPv4 bool) (net.PacketConn, int, error) {
+	if isIPv4 {
+		if conn, err := icmp.ListenPacket("ip4:icmp", "0.0.0.0"); err == nil {
+			return conn, protocolICMP, nil
+		}
+		conn, err := icmp.ListenPacket("udp4", "0.0.0.0")
+		return conn, protocolICMP, err
+	}
+	if conn, err := icmp.ListenPacket("ip6:ipv6-icmp", "::"); err == nil {
+		return conn, protocolIPv6ICMP, nil
+	}
+	conn, err := icmp.ListenPacket("udp6", "::")
+	return conn, protocolIPv6ICMP, err
+}
+
+func echoRequestType(isIPv4 bool) icmp.Type {
+	if isIPv4 {
+		return ipv4.ICMPTypeEcho
+	}
+	return ipv6.ICMPTypeEchoRequest
+}
+
+func isEchoReply(typ icmp.Type, isIPv4 bool) bool {
+	if isIPv4 {
+		return typ == ipv4.ICMPTypeEchoReply
+	}
+	return typ == ipv6.ICMPTypeEchoReply
+}
+
+// readICMP reads one packet from conn, returning the sender, the TTL (or
+// hop limit) it arrived with, and the number of bytes read. The TTL is
+// -1 when the platform does not report it for this socket type.
+func readICMP(conn net.PacketConn, isIPv4 bool, buf []byte) (n int, peer net.Addr, ttl int, err error) {
+	ic, isICMPConn := conn.(*icmp.PacketConn)
+	if isIPv4 {
+		var pc *ipv4.PacketConn
+		if isICMPConn {
+			pc = ic.IPv4PacketConn()
+		} else {
+			pc = ipv4.NewPacketConn(conn)
+		}
+		_ = pc.SetControlMessage(ipv4.FlagTTL, true)
+		var cm *ipv4.ControlMessage
+		n, cm, peer, err = pc.ReadFrom(buf)
+		ttl = -1
+		if cm != nil {
+			ttl = cm.TTL
+		}
+		return n, peer, ttl, err
+	}
+
+	var pc *ipv6.PacketConn
+	if isICMPConn {
+		pc = ic.IPv6PacketConn()
+	} else {
+		pc = ipv6.NewPacketConn(conn)
+	}
+	_ = pc.SetControlMessage(ipv6.FlagHopLimit, true)
+	var cm *ipv6.ControlMessage
+	n, cm, peer, err = pc.ReadFrom(buf)
+	ttl = -1
+	if cm != nil {
+		ttl = cm.HopLimit
+	}
+	return n, peer, ttl, err
+}
+
+// peerIP extracts the IP from the two address types icmp's raw and
+// unprivileged datagram sockets hand back.
+func peerIP(addr net.Addr) net.IP {
+	switch addr := addr.(type) {
+	case *net.IPAddr:
+		return addr.IP
+	case *net.UDPAddr:
+		return addr.IP
+	default:
+		return nil
+	}
+}
+
+// intStringer renders an int the way Stats.Meta expects.
+type intStringer int
+
+func (i intStringer) String() string {
+	return fmt.Sprintf("%d", int(i))
+}
+
+// stringStringer renders a plain string the way Stats.Meta expects.
+type stringStringer string
+
+func (s stringStringer) String() string {
+	return string(s)
+}
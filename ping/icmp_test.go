@@ -0,0 +1,121 @@
+package ping
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+func TestResolveICMPAddrsLiteralIP(t *testing.T) {
+	addrs, err := resolveICMPAddrs(context.Background(), net.DefaultResolver, "", "127.0.0.1")
+	if err != nil {
+		t.Fatalf("resolveICMPAddrs() error = %v", err)
+	}
+	if len(addrs) != 1 || !addrs[0].IP.Equal(net.ParseIP("127.0.0.1")) {
+		t.Errorf("resolveICMPAddrs() = %v, want [127.0.0.1]", addrs)
+	}
+}
+
+func TestResolveICMPAddrsNetworkFiltering(t *testing.T) {
+	host := "localhost"
+	resolved, err := net.DefaultResolver.LookupIPAddr(context.Background(), host)
+	if err != nil {
+		t.Skipf("LookupIPAddr(%q) error = %v, skipping (no resolver in sandbox)", host, err)
+	}
+	hasIPv4, hasIPv6 := false, false
+	for _, addr := range resolved {
+		if addr.IP.To4() != nil {
+			hasIPv4 = true
+		} else {
+			hasIPv6 = true
+		}
+	}
+
+	if hasIPv4 {
+		addrs, err := resolveICMPAddrs(context.Background(), net.DefaultResolver, "ip4", host)
+		if err != nil {
+			t.Fatalf("resolveICMPAddrs(ip4) error = %v", err)
+		}
+		for _, addr := range addrs {
+			if addr.IP.To4() == nil {
+				t.Errorf("resolveICMPAddrs(ip4) returned non-IPv4 address %v", addr)
+			}
+		}
+	}
+	if hasIPv6 {
+		addrs, err := resolveICMPAddrs(context.Background(), net.DefaultResolver, "ip6", host)
+		if err != nil {
+			t.Fatalf("resolveICMPAddrs(ip6) error = %v", err)
+		}
+		for _, addr := range addrs {
+			if addr.IP.To4() != nil {
+				t.Errorf("resolveICMPAddrs(ip6) returned non-IPv6 address %v", addr)
+			}
+		}
+	}
+}
+
+func TestResolveICMPAddrsNoMatchingFamily(t *testing.T) {
+	// A hostname with no address in the requested family yields an
+	// error; a literal IP is returned as-is regardless of the requested
+	// family (resolveICMPAddrs only filters names it actually looks up).
+	if _, err := resolveICMPAddrs(context.Background(), net.DefaultResolver, "ip6", "invalid.invalid"); err == nil {
+		t.Error("resolveICMPAddrs(ip6, invalid.invalid) error = nil, want an error")
+	}
+}
+
+func TestPeerIP(t *testing.T) {
+	ip := net.ParseIP("192.0.2.1")
+	cases := []struct {
+		name string
+		addr net.Addr
+		want net.IP
+	}{
+		{"IPAddr", &net.IPAddr{IP: ip}, ip},
+		{"UDPAddr", &net.UDPAddr{IP: ip, Port: 0}, ip},
+		{"unknown", dummyAddr{}, nil},
+	}
+	for _, c := range cases {
+		got := peerIP(c.addr)
+		if (got == nil) != (c.want == nil) || (got != nil && !got.Equal(c.want)) {
+			t.Errorf("%s: peerIP() = %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+type dummyAddr struct{}
+
+func (dummyAddr) Network() string { return "dummy" }
+func (dummyAddr) String() string  { return "dummy" }
+
+func TestIsEchoReply(t *testing.T) {
+	cases := []struct {
+		name   string
+		typ    icmp.Type
+		isIPv4 bool
+		want   bool
+	}{
+		{"ipv4 reply", ipv4.ICMPTypeEchoReply, true, true},
+		{"ipv4 request", ipv4.ICMPTypeEcho, true, false},
+		{"ipv6 reply", ipv6.ICMPTypeEchoReply, false, true},
+		{"ipv6 request", ipv6.ICMPTypeEchoRequest, false, false},
+	}
+	for _, c := range cases {
+		if got := isEchoReply(c.typ, c.isIPv4); got != c.want {
+			t.Errorf("%s: isEchoReply() = %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestEchoRequestType(t *testing.T) {
+	if echoRequestType(true) != ipv4.ICMPTypeEcho {
+		t.Error("echoRequestType(true) != ipv4.ICMPTypeEcho")
+	}
+	if echoRequestType(false) != ipv6.ICMPTypeEchoRequest {
+		t.Error("echoRequestType(false) != ipv6.ICMPTypeEchoRequest")
+	}
+}
@@ -0,0 +1,81 @@
+// Package metrics exposes the Prometheus collectors tcping updates for
+// every probe it sends, and serves them over HTTP so tcping can run as a
+// long-lived black-box exporter alongside its interactive mode.
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var registry = prometheus.NewRegistry()
+
+var (
+	// RequestsTotal counts every probe sent, labeled by the target URL
+	// and the resolved address it was sent to.
+	RequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "tcping_requests_total",
+		Help: "Total number of probes sent, labeled by target URL and resolved address.",
+	}, []string{"url", "address"})
+
+	// ResponsesTotal counts probe responses, labeled by the target URL,
+	// resolved address and status ("ok", "error" or an HTTP status
+	// code). The first probe to each target, which the Pinger discards
+	// from its statistics, is counted in RequestsTotal but not here.
+	ResponsesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "tcping_responses_total",
+		Help: "Total number of probe responses, labeled by target URL, resolved address and status.",
+	}, []string{"url", "address", "status"})
+
+	// Duration observes the round-trip duration of each probe in
+	// seconds, labeled the same way as ResponsesTotal.
+	Duration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "tcping_duration_seconds",
+		Help:    "Probe round-trip duration in seconds, labeled by target URL, resolved address and status.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"url", "address", "status"})
+)
+
+func init() {
+	registry.MustRegister(RequestsTotal, ResponsesTotal, Duration)
+}
+
+// ObserveRequest records that a probe was sent to address for url.
+func ObserveRequest(url, address string) {
+	RequestsTotal.WithLabelValues(url, address).Inc()
+}
+
+// ObserveResponse records the outcome of a probe: its status and its
+// round-trip duration.
+func ObserveResponse(url, address, status string, duration time.Duration) {
+	ResponsesTotal.WithLabelValues(url, address, status).Inc()
+	Duration.WithLabelValues(url, address, status).Observe(duration.Seconds())
+}
+
+// Handler returns the http.Handler that serves the registered collectors
+// in the Prometheus exposition format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// ListenAndServe serves Handler at /metrics on addr until ctx is done.
+func ListenAndServe(ctx context.Context, addr string) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", Handler())
+	server := &http.Server{Addr: addr, Handler: mux}
+
+	go func() {
+		<-ctx.Done()
+		_ = server.Close()
+	}()
+
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -7,19 +7,24 @@ import (
 	"fmt"
 	"html/template"
 	"io"
+	"math/rand"
 	"net"
 	"net/url"
 	"os"
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
-	"github.com/mattn/go-isatty"
-	"gonum.org/v1/gonum/stat"
+	"github.com/cloverstd/tcping/ping/metrics"
 	"slices"
 )
 
+// DefaultInterval is the interval used between probes when the caller
+// does not provide one.
+const DefaultInterval = time.Second
+
 var pinger = map[Protocol]Factory{}
 
 type Factory func(url *url.URL, op *Option) (Ping, error)
@@ -43,6 +48,8 @@ func (protocol Protocol) String() string {
 		return "http"
 	case HTTPS:
 		return "https"
+	case ICMP:
+		return "icmp"
 	}
 	return "unknown"
 }
@@ -54,6 +61,8 @@ const (
 	HTTP
 	// HTTPS is https protocol
 	HTTPS
+	// ICMP is icmp echo protocol
+	ICMP
 )
 
 // NewProtocol convert protocol string to Protocol
@@ -65,6 +74,8 @@ func NewProtocol(protocol string) (Protocol, error) {
 		return HTTP, nil
 	case HTTPS.String():
 		return HTTPS, nil
+	case ICMP.String():
+		return ICMP, nil
 	}
 	return 0, fmt.Errorf("protocol %s not support", protocol)
 }
@@ -74,6 +85,15 @@ type Option struct {
 	Resolver *net.Resolver
 	Proxy    *url.URL
 	UA       string
+
+	// Network forces the address family used to resolve and dial the
+	// target: "ip4" or "ip6". Empty lets the resolver pick.
+	Network string
+
+	// RandomizeAddress picks a random resolved address for each probe
+	// instead of round-robining through them in order, so a dual-stack
+	// or multi-A-record host exercises all of its endpoints either way.
+	RandomizeAddress bool
 }
 
 // Target is a ping
@@ -125,31 +145,77 @@ type Ping interface {
 	Ping(ctx context.Context) *Stats
 }
 
-func NewPinger(out io.Writer, url *url.URL, ping Ping, interval time.Duration, counter int) *Pinger {
+// ProbeTarget pairs a Ping prober with the URL it probes, the unit
+// NewPinger schedules one of alongside the others given to it.
+type ProbeTarget struct {
+	URL  *url.URL
+	Ping Ping
+}
+
+// NewPinger builds a Pinger that probes one or more targets concurrently,
+// sharing a single interval, counter and shutdown signal. A nil reporter
+// defaults to plain text output.
+func NewPinger(out io.Writer, reporter Reporter, interval time.Duration, counter int, targets ...ProbeTarget) *Pinger {
+	subs := make([]*subPinger, 0, len(targets))
+	for _, target := range targets {
+		subs = append(subs, &subPinger{
+			url:             target.URL,
+			ping:            target.Ping,
+			familyDurations: map[string][]float64{},
+			familyFailed:    map[string]int{},
+			phaseDurations:  map[string][]float64{},
+		})
+	}
+	if reporter == nil {
+		reporter = &textReporter{}
+	}
 	return &Pinger{
 		stopC:    make(chan struct{}),
 		counter:  counter,
 		interval: interval,
 		out:      out,
-		url:      url,
-		ping:     ping,
+		reporter: reporter,
+		targets:  subs,
 	}
 }
 
-type Pinger struct {
+// subPinger holds the running statistics for a single target. Each
+// subPinger is only ever touched by the one goroutine probing it, so no
+// synchronization is needed across its fields.
+type subPinger struct {
+	url  *url.URL
 	ping Ping
 
+	durations   []float64
+	failedTotal int
+
+	// familyDurations and familyFailed break the same counters down by
+	// resolved address family ("ip4"/"ip6"), so Summarize can report
+	// dual-stack hosts separately.
+	familyDurations map[string][]float64
+	familyFailed    map[string]int
+
+	// phaseDurations collects any time.Duration values probes report in
+	// Stats.Meta (e.g. the HTTP prober's "dns"/"connect"/"tls"/"ttfb"
+	// phases), keyed by meta key, so Summarize can report percentiles
+	// per phase.
+	phaseDurations map[string][]float64
+}
+
+type Pinger struct {
 	stopOnce sync.Once
 	stopC    chan struct{}
 
-	out io.Writer
+	out      io.Writer
+	reporter Reporter
+	// outMu serializes every write into out/reporter, since each target
+	// is probed from its own goroutine but they all share one writer.
+	outMu sync.Mutex
 
-	url *url.URL
+	targets []*subPinger
 
-	interval    time.Duration
-	counter     int
-	durations   []float64
-	failedTotal int
+	interval time.Duration
+	counter  int
 }
 
 func (p *Pinger) Stop() {
@@ -162,6 +228,9 @@ func (p *Pinger) Done() <-chan struct{} {
 	return p.stopC
 }
 
+// Ping probes every target concurrently against a shared context, until
+// Stop is called or every target reaches its counter. Output from all
+// targets is interleaved on p.out as probes complete.
 func (p *Pinger) Ping() {
 	defer p.Stop()
 
@@ -173,6 +242,19 @@ func (p *Pinger) Ping() {
 		cancel()
 	}()
 
+	var wg sync.WaitGroup
+	wg.Add(len(p.targets))
+	for _, target := range p.targets {
+		target := target
+		go func() {
+			defer wg.Done()
+			p.pingTarget(ctx, target)
+		}()
+	}
+	wg.Wait()
+}
+
+func (p *Pinger) pingTarget(ctx context.Context, target *subPinger) {
 	interval := DefaultInterval
 	if p.interval > 0 {
 		interval = p.interval
@@ -185,59 +267,63 @@ func (p *Pinger) Ping() {
 	for !stop {
 		select {
 		case <-timer.C:
-			stats := p.ping.Ping(ctx)
+			stats := target.ping.Ping(ctx)
+			metrics.ObserveRequest(target.url.String(), stats.Address)
 			if !hasDiscardedFirst {
 				hasDiscardedFirst = true
 			} else {
-				p.logStats(stats)
-				if p.counter > 0 && p.getTotal() > p.counter-1 {
+				p.logStats(target, stats)
+				if p.counter > 0 && target.getTotal() > p.counter-1 {
 					stop = true
 				}
 			}
-			p.printPingResult(stats)
+			p.outMu.Lock()
+			p.reporter.PrintResult(p.out, target.url, stats)
+			p.outMu.Unlock()
 			timer.Reset(interval)
 		case <-p.Done():
 			stop = true
+		case <-ctx.Done():
+			stop = true
 		}
 	}
 }
 
+// Summarize reports one statistics block per target, in the order they
+// were given to NewPinger. Targets whose probes hit more than one
+// resolved address family also get a block per family, so a dual-stack
+// host's IPv4 and IPv6 latencies can be compared.
 func (p *Pinger) Summarize() {
-
-	const tpl = `
-Ping statistics %s
-	%d probes sent.
-	%d successful, %d failed.
-Approximate trip times:
-	Minimum = %s
-	Maximum = %s
-	Average = %s
-	p50     = %s
-	p95     = %s
-	p99     = %s
-`
-
-	slices.Sort(p.durations)
-
-	pTotal := time.Duration(p.getTotal())
-	var average time.Duration
-	if pTotal != 0 {
-		average = p.getAvgDuration()
+	for _, target := range p.targets {
+		slices.Sort(target.durations)
+		families := make(map[string][]float64, len(target.familyDurations))
+		if len(target.familyDurations) > 1 {
+			for family, durations := range target.familyDurations {
+				slices.Sort(durations)
+				families[family] = durations
+			}
+		}
+		for _, durations := range target.phaseDurations {
+			slices.Sort(durations)
+		}
+		p.reporter.Summarize(p.out, TargetSummary{
+			Target:          target.url,
+			Durations:       target.durations,
+			FailedTotal:     target.failedTotal,
+			FamilyDurations: families,
+			FamilyFailed:    target.familyFailed,
+			PhaseDurations:  target.phaseDurations,
+		})
 	}
-	_, _ = fmt.Fprintf(p.out, tpl, p.url.String(), p.getTotal(), p.getSuccessTotal(), p.getFailedTotal(),
-		p.getMinDuration(), p.getMaxDuration(), average,
-		time.Duration(stat.Quantile(0.5, stat.LinInterp, p.durations, nil)),
-		time.Duration(stat.Quantile(0.95, stat.LinInterp, p.durations, nil)),
-		time.Duration(stat.Quantile(0.99, stat.LinInterp, p.durations, nil)))
 }
 
-func (p *Pinger) formatError(err error) string {
+func formatError(err error) string {
 	switch err := err.(type) {
 	case *url.Error:
 		if err.Timeout() {
 			return "timeout"
 		}
-		return p.formatError(err.Err)
+		return formatError(err.Err)
 	case net.Error:
 		if err.Timeout() {
 			return "timeout"
@@ -256,10 +342,22 @@ func (p *Pinger) formatError(err error) string {
 	return err.Error()
 }
 
-func (p *Pinger) logStats(stats *Stats) {
-	p.durations = append(p.durations, float64(stats.Duration.Nanoseconds()))
+func (p *Pinger) logStats(target *subPinger, stats *Stats) {
+	target.durations = append(target.durations, float64(stats.Duration.Nanoseconds()))
+	metrics.ObserveResponse(target.url.String(), stats.Address, metricsStatus(stats), stats.Duration)
+	if family := addressFamily(stats.Address); family != "" {
+		target.familyDurations[family] = append(target.familyDurations[family], float64(stats.Duration.Nanoseconds()))
+		if stats.Error != nil {
+			target.familyFailed[family]++
+		}
+	}
+	for key, value := range stats.Meta {
+		if duration, ok := value.(time.Duration); ok {
+			target.phaseDurations[key] = append(target.phaseDurations[key], float64(duration.Nanoseconds()))
+		}
+	}
 	if stats.Error != nil {
-		p.failedTotal++
+		target.failedTotal++
 		if errors.Is(stats.Error, context.Canceled) {
 			// ignore cancel
 			return
@@ -267,82 +365,54 @@ func (p *Pinger) logStats(stats *Stats) {
 	}
 }
 
-func (p *Pinger) getTotal() int {
-	return len(p.durations)
-}
-
-func (p *Pinger) getMinDuration() time.Duration {
-	min := stat.Quantile(0, stat.Empirical, p.durations, nil)
-
-	return time.Duration(min)
-}
-
-func (p *Pinger) getMaxDuration() time.Duration {
-	max := stat.Quantile(1, stat.Empirical, p.durations, nil)
-	return time.Duration(max)
-}
-
-func (p *Pinger) getAvgDuration() time.Duration {
-	avg := stat.Mean(p.durations, nil)
-	return time.Duration(avg)
-}
-
-func (p *Pinger) getFailedTotal() int {
-	return p.failedTotal
-}
-
-func (p *Pinger) getSuccessTotal() int {
-	return p.getTotal() - p.getFailedTotal()
-}
-
-func (p *Pinger) printPingResult(stats *Stats) {
-	status := "Failed"
-	if stats.Connected {
-		status = "connected"
+// addressFamily returns "ip4" or "ip6" for a resolved address, or "" if
+// address isn't a parseable IP (e.g. a probe that failed before resolving).
+func addressFamily(address string) string {
+	host := address
+	if h, _, err := net.SplitHostPort(address); err == nil {
+		host = h
 	}
-
-	const colorRed = "\033[0;31m"
-	const colorNone = "\033[0m"
-
-	timestampFmt := time.Now().Format(time.StampMilli)
-
-	statsDuration := formatDurationMs(stats.Duration)
-	statsDNSDuration := formatDurationMs(stats.DNSDuration)
-	if stats.Error != nil {
-		var colorBefore, colorAfter string
-		if isTerminal(p.out) {
-			colorBefore = colorRed
-			colorAfter = colorNone
-		} else {
-			colorBefore = ""
-			colorAfter = ""
-		}
-		_, _ = fmt.Fprintf(p.out, "%s%s: Ping %s(%s) %s(%s) - time=%s dns=%s%s", colorBefore, timestampFmt, p.url.String(), stats.Address, status, p.formatError(stats.Error), statsDuration, statsDNSDuration, colorAfter)
-	} else {
-		_, _ = fmt.Fprintf(p.out, "%s: Ping %s(%s) %s - time=%s dns=%s", timestampFmt, p.url.String(), stats.Address, status, statsDuration, statsDNSDuration)
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return ""
 	}
-	if len(stats.Meta) > 0 {
-		_, _ = fmt.Fprintf(p.out, " %s", stats.FormatMeta())
-	}
-	_, _ = fmt.Fprint(p.out, "\n")
-	if stats.Extra != nil {
-		_, _ = fmt.Fprintf(p.out, " %s\n", strings.TrimSpace(stats.Extra.String()))
+	if ip.To4() != nil {
+		return "ip4"
 	}
+	return "ip6"
 }
 
-func formatDurationMs(duration time.Duration) string {
-	ms := float64(duration.Round(time.Microsecond).Microseconds()) / 1000.0
-	return fmt.Sprintf("%.3fms", ms)
+// pickAddr selects one of addrs for a probe: round-robin through idx by
+// default, or uniformly at random when randomize is set. Shared by every
+// protocol factory that resolves multiple addresses for a host (TCP,
+// ICMP, HTTP), so a dual-stack or multi-A/AAAA-record host is exercised
+// the same way regardless of protocol.
+func pickAddr[T any](addrs []T, idx *uint32, randomize bool) T {
+	if len(addrs) == 1 {
+		return addrs[0]
+	}
+	if randomize {
+		return addrs[rand.Intn(len(addrs))]
+	}
+	i := atomic.AddUint32(idx, 1) - 1
+	return addrs[int(i)%len(addrs)]
 }
 
-func isTerminal(out io.Writer) bool {
-	if out == nil {
-		return false
+// metricsStatus derives the "status" label used for the Prometheus
+// response metrics: the HTTP status code when the protocol recorded one
+// in Stats.Meta, otherwise "ok" or "error".
+func metricsStatus(stats *Stats) string {
+	if status, ok := stats.Meta["status"]; ok {
+		return status.String()
 	}
-	if f, ok := out.(*os.File); ok {
-		return isatty.IsTerminal(f.Fd())
+	if stats.Error != nil {
+		return "error"
 	}
-	return false
+	return "ok"
+}
+
+func (target *subPinger) getTotal() int {
+	return len(target.durations)
 }
 
 // Result ...
@@ -0,0 +1,49 @@
+package ping
+
+import "testing"
+
+func TestPickAddrRoundRobin(t *testing.T) {
+	addrs := []string{"a", "b", "c"}
+	var idx uint32
+
+	for i, want := range []string{"a", "b", "c", "a", "b"} {
+		got := pickAddr(addrs, &idx, false)
+		if got != want {
+			t.Errorf("call %d: pickAddr() = %q, want %q", i, got, want)
+		}
+	}
+}
+
+func TestPickAddrSingle(t *testing.T) {
+	addrs := []string{"only"}
+	var idx uint32
+
+	for i := 0; i < 3; i++ {
+		if got := pickAddr(addrs, &idx, false); got != "only" {
+			t.Errorf("pickAddr() = %q, want %q", got, "only")
+		}
+	}
+}
+
+func TestPickAddrRandomizeStaysInRange(t *testing.T) {
+	addrs := []string{"a", "b", "c"}
+	var idx uint32
+
+	seen := map[string]bool{}
+	for i := 0; i < 50; i++ {
+		got := pickAddr(addrs, &idx, true)
+		if !seen[got] {
+			seen[got] = true
+		}
+		found := false
+		for _, addr := range addrs {
+			if addr == got {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Fatalf("pickAddr() = %q, not in %v", got, addrs)
+		}
+	}
+}
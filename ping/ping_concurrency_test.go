@@ -0,0 +1,36 @@
+package ping
+
+import (
+	"bytes"
+	"context"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// fakePing returns an immediate successful Stats on every call, so the
+// scheduler in pingTarget can run as fast as possible.
+type fakePing struct{}
+
+func (fakePing) Ping(context.Context) *Stats {
+	return &Stats{Connected: true, Address: "127.0.0.1:0"}
+}
+
+// TestPingerConcurrentOutputIsSerialized probes several targets at once
+// with a tiny interval and a shared buffer, under `go test -race`, to
+// catch unsynchronized writes into Pinger.out/reporter.
+func TestPingerConcurrentOutputIsSerialized(t *testing.T) {
+	var out bytes.Buffer
+	targets := make([]ProbeTarget, 0, 8)
+	for i := 0; i < 8; i++ {
+		u, err := url.Parse("tcp://target.invalid:80")
+		if err != nil {
+			t.Fatalf("url.Parse() error = %v", err)
+		}
+		targets = append(targets, ProbeTarget{URL: u, Ping: fakePing{}})
+	}
+
+	pinger := NewPinger(&out, nil, time.Millisecond, 5, targets...)
+	pinger.Ping()
+	pinger.Summarize()
+}
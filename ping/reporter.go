@@ -0,0 +1,362 @@
+package ping
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-isatty"
+	"gonum.org/v1/gonum/stat"
+)
+
+// OutputFormat selects how a Pinger renders individual probe results and
+// summaries.
+type OutputFormat string
+
+const (
+	// OutputText is the default human-readable format.
+	OutputText OutputFormat = "text"
+	// OutputJSON emits one JSON object per line, suitable for jq or
+	// telegraf's exec input.
+	OutputJSON OutputFormat = "json"
+	// OutputCSV emits a header row followed by one row per probe, and a
+	// separate summary header followed by one row per summary block.
+	OutputCSV OutputFormat = "csv"
+)
+
+// NewReporter returns the Reporter for format, or an error if format isn't
+// recognized. An empty format returns the text reporter.
+func NewReporter(format OutputFormat) (Reporter, error) {
+	switch format {
+	case "", OutputText:
+		return &textReporter{}, nil
+	case OutputJSON:
+		return &jsonReporter{}, nil
+	case OutputCSV:
+		return &csvReporter{}, nil
+	}
+	return nil, fmt.Errorf("output format %s not supported", format)
+}
+
+// Reporter renders probe results and end-of-run summaries. Pinger holds
+// exactly one, chosen by OutputFormat, so text/json/csv all flow through
+// the same call sites.
+type Reporter interface {
+	PrintResult(out io.Writer, target *url.URL, stats *Stats)
+	Summarize(out io.Writer, summary TargetSummary)
+}
+
+// TargetSummary carries the per-target statistics Pinger.Summarize has
+// accumulated, in a form Reporter implementations can render without
+// reaching back into Pinger's internals.
+type TargetSummary struct {
+	Target      *url.URL
+	Durations   []float64
+	FailedTotal int
+
+	// FamilyDurations is only populated when the target resolved to more
+	// than one address family. FamilyFailed holds per-family failure
+	// counts for every target; reporters read it only for the families
+	// in FamilyDurations.
+	FamilyDurations map[string][]float64
+	FamilyFailed    map[string]int
+
+	// PhaseDurations holds any time.Duration values probes reported in
+	// Stats.Meta (e.g. the HTTP prober's "dns"/"connect"/"tls"/"ttfb"
+	// phases), keyed by meta key, so a phase can be summarized the same
+	// way as the overall round-trip time.
+	PhaseDurations map[string][]float64
+}
+
+// textReporter reproduces tcping's original human-readable output.
+type textReporter struct{}
+
+func (r *textReporter) PrintResult(out io.Writer, target *url.URL, stats *Stats) {
+	status := "Failed"
+	if stats.Connected {
+		status = "connected"
+	}
+
+	const colorRed = "\033[0;31m"
+	const colorNone = "\033[0m"
+
+	timestampFmt := time.Now().Format(time.StampMilli)
+
+	statsDuration := formatDurationMs(stats.Duration)
+	statsDNSDuration := formatDurationMs(stats.DNSDuration)
+	if stats.Error != nil {
+		var colorBefore, colorAfter string
+		if isTerminal(out) {
+			colorBefore = colorRed
+			colorAfter = colorNone
+		}
+		_, _ = fmt.Fprintf(out, "%s%s: Ping %s(%s) %s(%s) - time=%s dns=%s%s", colorBefore, timestampFmt, target.String(), stats.Address, status, formatError(stats.Error), statsDuration, statsDNSDuration, colorAfter)
+	} else {
+		_, _ = fmt.Fprintf(out, "%s: Ping %s(%s) %s - time=%s dns=%s", timestampFmt, target.String(), stats.Address, status, statsDuration, statsDNSDuration)
+	}
+	if len(stats.Meta) > 0 {
+		_, _ = fmt.Fprintf(out, " %s", stats.FormatMeta())
+	}
+	_, _ = fmt.Fprint(out, "\n")
+	if stats.Extra != nil {
+		_, _ = fmt.Fprintf(out, " %s\n", strings.TrimSpace(stats.Extra.String()))
+	}
+}
+
+const statsTpl = `
+Ping statistics %s
+	%d probes sent.
+	%d successful, %d failed.
+Approximate trip times:
+	Minimum = %s
+	Maximum = %s
+	Average = %s
+	p50     = %s
+	p95     = %s
+	p99     = %s
+`
+
+func (r *textReporter) Summarize(out io.Writer, summary TargetSummary) {
+	printStatsBlock(out, summary.Target.String(), summary.Durations, summary.FailedTotal)
+
+	for _, family := range sortedKeys(summary.FamilyDurations) {
+		printStatsBlock(out, fmt.Sprintf("%s (%s)", summary.Target.String(), family), summary.FamilyDurations[family], summary.FamilyFailed[family])
+	}
+
+	for _, phase := range sortedKeys(summary.PhaseDurations) {
+		printStatsBlock(out, fmt.Sprintf("%s (phase=%s)", summary.Target.String(), phase), summary.PhaseDurations[phase], 0)
+	}
+}
+
+// sortedKeys returns durations' keys in sorted order, so phase and
+// family blocks print in a stable order across runs.
+func sortedKeys(durations map[string][]float64) []string {
+	keys := make([]string, 0, len(durations))
+	for key := range durations {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func printStatsBlock(out io.Writer, label string, durations []float64, failedTotal int) {
+	total := len(durations)
+	successTotal := total - failedTotal
+
+	var average time.Duration
+	if total != 0 {
+		average = time.Duration(stat.Mean(durations, nil))
+	}
+	_, _ = fmt.Fprintf(out, statsTpl, label, total, successTotal, failedTotal,
+		time.Duration(stat.Quantile(0, stat.Empirical, durations, nil)),
+		time.Duration(stat.Quantile(1, stat.Empirical, durations, nil)),
+		average,
+		time.Duration(stat.Quantile(0.5, stat.LinInterp, durations, nil)),
+		time.Duration(stat.Quantile(0.95, stat.LinInterp, durations, nil)),
+		time.Duration(stat.Quantile(0.99, stat.LinInterp, durations, nil)))
+}
+
+func formatDurationMs(duration time.Duration) string {
+	ms := float64(duration.Round(time.Microsecond).Microseconds()) / 1000.0
+	return fmt.Sprintf("%.3fms", ms)
+}
+
+func isTerminal(out io.Writer) bool {
+	if out == nil {
+		return false
+	}
+	if f, ok := out.(*os.File); ok {
+		return isatty.IsTerminal(f.Fd())
+	}
+	return false
+}
+
+// jsonRecord is the wire shape for both json probe results and summaries.
+// Error is serialized as a plain string since Stats.Error is an `error`,
+// which marshals to `{}` on its own.
+type jsonRecord struct {
+	Timestamp   time.Time         `json:"timestamp"`
+	Target      string            `json:"target"`
+	Address     string            `json:"address,omitempty"`
+	Connected   bool              `json:"connected"`
+	Duration    time.Duration     `json:"duration"`
+	DNSDuration time.Duration     `json:"dnsDuration"`
+	Error       string            `json:"error,omitempty"`
+	Meta        map[string]string `json:"meta,omitempty"`
+
+	// Summary-only fields, omitted for per-probe records.
+	Family  string  `json:"family,omitempty"`
+	Phase   string  `json:"phase,omitempty"`
+	Total   int     `json:"total,omitempty"`
+	Success int     `json:"success,omitempty"`
+	Failed  int     `json:"failed,omitempty"`
+	Min     float64 `json:"minMs,omitempty"`
+	Max     float64 `json:"maxMs,omitempty"`
+	Avg     float64 `json:"avgMs,omitempty"`
+	P50     float64 `json:"p50Ms,omitempty"`
+	P95     float64 `json:"p95Ms,omitempty"`
+	P99     float64 `json:"p99Ms,omitempty"`
+}
+
+func stringifyMeta(meta map[string]fmt.Stringer) map[string]string {
+	if len(meta) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(meta))
+	for key, value := range meta {
+		out[key] = value.String()
+	}
+	return out
+}
+
+// jsonReporter emits one JSON object per line (JSON Lines), one per probe
+// or summary block.
+type jsonReporter struct{}
+
+func (r *jsonReporter) PrintResult(out io.Writer, target *url.URL, stats *Stats) {
+	record := jsonRecord{
+		Timestamp:   time.Now(),
+		Target:      target.String(),
+		Address:     stats.Address,
+		Connected:   stats.Connected,
+		Duration:    stats.Duration,
+		DNSDuration: stats.DNSDuration,
+		Meta:        stringifyMeta(stats.Meta),
+	}
+	if stats.Error != nil {
+		record.Error = formatError(stats.Error)
+	}
+	writeJSONLine(out, record)
+}
+
+func (r *jsonReporter) Summarize(out io.Writer, summary TargetSummary) {
+	writeJSONLine(out, summaryRecord(summary.Target.String(), "", "", summary.Durations, summary.FailedTotal))
+
+	for _, family := range sortedKeys(summary.FamilyDurations) {
+		writeJSONLine(out, summaryRecord(summary.Target.String(), family, "", summary.FamilyDurations[family], summary.FamilyFailed[family]))
+	}
+	for _, phase := range sortedKeys(summary.PhaseDurations) {
+		writeJSONLine(out, summaryRecord(summary.Target.String(), "", phase, summary.PhaseDurations[phase], 0))
+	}
+}
+
+func summaryRecord(target, family, phase string, durations []float64, failedTotal int) jsonRecord {
+	total := len(durations)
+	var avg float64
+	if total != 0 {
+		avg = stat.Mean(durations, nil) / float64(time.Millisecond)
+	}
+	return jsonRecord{
+		Timestamp: time.Now(),
+		Target:    target,
+		Family:    family,
+		Phase:     phase,
+		Total:     total,
+		Success:   total - failedTotal,
+		Failed:    failedTotal,
+		Min:       stat.Quantile(0, stat.Empirical, durations, nil) / float64(time.Millisecond),
+		Max:       stat.Quantile(1, stat.Empirical, durations, nil) / float64(time.Millisecond),
+		Avg:       avg,
+		P50:       stat.Quantile(0.5, stat.LinInterp, durations, nil) / float64(time.Millisecond),
+		P95:       stat.Quantile(0.95, stat.LinInterp, durations, nil) / float64(time.Millisecond),
+		P99:       stat.Quantile(0.99, stat.LinInterp, durations, nil) / float64(time.Millisecond),
+	}
+}
+
+func writeJSONLine(out io.Writer, record jsonRecord) {
+	encoded, err := json.Marshal(record)
+	if err != nil {
+		return
+	}
+	_, _ = out.Write(encoded)
+	_, _ = fmt.Fprintln(out)
+}
+
+var csvHeader = []string{"timestamp", "target", "address", "connected", "duration_ms", "dns_duration_ms", "error", "meta"}
+
+// csvReporter emits a stable header once before the first probe row, and
+// a separate summary header once before the first summary row.
+type csvReporter struct {
+	headerWritten        bool
+	summaryHeaderWritten bool
+}
+
+func (r *csvReporter) writeHeader(out io.Writer) {
+	if r.headerWritten {
+		return
+	}
+	r.headerWritten = true
+	w := csv.NewWriter(out)
+	_ = w.Write(csvHeader)
+	w.Flush()
+}
+
+func (r *csvReporter) PrintResult(out io.Writer, target *url.URL, stats *Stats) {
+	r.writeHeader(out)
+
+	errString := ""
+	if stats.Error != nil {
+		errString = formatError(stats.Error)
+	}
+	row := []string{
+		time.Now().Format(time.RFC3339Nano),
+		target.String(),
+		stats.Address,
+		strconv.FormatBool(stats.Connected),
+		strconv.FormatFloat(float64(stats.Duration)/float64(time.Millisecond), 'f', -1, 64),
+		strconv.FormatFloat(float64(stats.DNSDuration)/float64(time.Millisecond), 'f', -1, 64),
+		errString,
+		stats.FormatMeta(),
+	}
+	w := csv.NewWriter(out)
+	_ = w.Write(row)
+	w.Flush()
+}
+
+var csvSummaryHeader = []string{"target", "family", "phase", "total", "success", "failed", "min_ms", "max_ms", "avg_ms", "p50_ms", "p95_ms", "p99_ms"}
+
+func (r *csvReporter) Summarize(out io.Writer, summary TargetSummary) {
+	w := csv.NewWriter(out)
+	if !r.summaryHeaderWritten {
+		r.summaryHeaderWritten = true
+		_ = w.Write(csvSummaryHeader)
+	}
+	writeCSVSummaryRow(w, summary.Target.String(), "", "", summary.Durations, summary.FailedTotal)
+
+	for _, family := range sortedKeys(summary.FamilyDurations) {
+		writeCSVSummaryRow(w, summary.Target.String(), family, "", summary.FamilyDurations[family], summary.FamilyFailed[family])
+	}
+	for _, phase := range sortedKeys(summary.PhaseDurations) {
+		writeCSVSummaryRow(w, summary.Target.String(), "", phase, summary.PhaseDurations[phase], 0)
+	}
+	w.Flush()
+}
+
+func writeCSVSummaryRow(w *csv.Writer, target, family, phase string, durations []float64, failedTotal int) {
+	total := len(durations)
+	var avg float64
+	if total != 0 {
+		avg = stat.Mean(durations, nil) / float64(time.Millisecond)
+	}
+	_ = w.Write([]string{
+		target,
+		family,
+		phase,
+		strconv.Itoa(total),
+		strconv.Itoa(total - failedTotal),
+		strconv.Itoa(failedTotal),
+		strconv.FormatFloat(stat.Quantile(0, stat.Empirical, durations, nil)/float64(time.Millisecond), 'f', -1, 64),
+		strconv.FormatFloat(stat.Quantile(1, stat.Empirical, durations, nil)/float64(time.Millisecond), 'f', -1, 64),
+		strconv.FormatFloat(avg, 'f', -1, 64),
+		strconv.FormatFloat(stat.Quantile(0.5, stat.LinInterp, durations, nil)/float64(time.Millisecond), 'f', -1, 64),
+		strconv.FormatFloat(stat.Quantile(0.95, stat.LinInterp, durations, nil)/float64(time.Millisecond), 'f', -1, 64),
+		strconv.FormatFloat(stat.Quantile(0.99, stat.LinInterp, durations, nil)/float64(time.Millisecond), 'f', -1, 64),
+	})
+}
@@ -0,0 +1,118 @@
+package ping
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func mustParseURL(t *testing.T, raw string) *url.URL {
+	t.Helper()
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatalf("url.Parse(%q) error = %v", raw, err)
+	}
+	return u
+}
+
+func TestCSVReporterSummarizeHeaderWrittenOnce(t *testing.T) {
+	var out bytes.Buffer
+	reporter := &csvReporter{}
+
+	for _, target := range []string{"tcp://a.invalid:80", "tcp://b.invalid:80"} {
+		reporter.Summarize(&out, TargetSummary{
+			Target:    mustParseURL(t, target),
+			Durations: []float64{float64(time.Millisecond)},
+		})
+	}
+
+	lines := strings.Split(strings.TrimRight(out.String(), "\n"), "\n")
+	headerCount := 0
+	for _, line := range lines {
+		if line == strings.Join(csvSummaryHeader, ",") {
+			headerCount++
+		}
+	}
+	if headerCount != 1 {
+		t.Errorf("got %d header rows across 2 Summarize calls, want 1\noutput:\n%s", headerCount, out.String())
+	}
+	if len(lines) != 3 {
+		t.Errorf("got %d lines, want 1 header + 2 data rows", len(lines))
+	}
+}
+
+func TestCSVReporterPrintResultHeaderWrittenOnce(t *testing.T) {
+	var out bytes.Buffer
+	reporter := &csvReporter{}
+	target := mustParseURL(t, "tcp://a.invalid:80")
+
+	reporter.PrintResult(&out, target, &Stats{Connected: true})
+	reporter.PrintResult(&out, target, &Stats{Connected: true})
+
+	lines := strings.Split(strings.TrimRight(out.String(), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want 1 header + 2 data rows:\n%s", len(lines), out.String())
+	}
+	if lines[0] != strings.Join(csvHeader, ",") {
+		t.Errorf("header = %q, want %q", lines[0], strings.Join(csvHeader, ","))
+	}
+}
+
+func TestJSONReporterPrintResultEmitsErrorAsString(t *testing.T) {
+	var out bytes.Buffer
+	reporter := &jsonReporter{}
+	target := mustParseURL(t, "tcp://a.invalid:80")
+
+	reporter.PrintResult(&out, target, &Stats{Error: errTimeout})
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v\noutput: %s", err, out.String())
+	}
+	if _, ok := decoded["error"].(string); !ok {
+		t.Errorf("decoded error field = %#v, want a string", decoded["error"])
+	}
+}
+
+var errTimeout = &testTimeoutErr{}
+
+type testTimeoutErr struct{}
+
+func (*testTimeoutErr) Error() string { return "deadline exceeded" }
+
+func TestAddressFamily(t *testing.T) {
+	cases := map[string]string{
+		"93.184.216.34:80":                        "ip4",
+		"93.184.216.34":                           "ip4",
+		"[2606:2800:220:1:248:1893:25c8:1946]:80": "ip6",
+		"2606:2800:220:1:248:1893:25c8:1946":      "ip6",
+		"":                                        "",
+		"not-an-ip":                               "",
+	}
+	for address, want := range cases {
+		if got := addressFamily(address); got != want {
+			t.Errorf("addressFamily(%q) = %q, want %q", address, got, want)
+		}
+	}
+}
+
+func TestMetricsStatus(t *testing.T) {
+	cases := []struct {
+		name  string
+		stats *Stats
+		want  string
+	}{
+		{"ok", &Stats{}, "ok"},
+		{"error", &Stats{Error: errTimeout}, "error"},
+		{"status meta wins", &Stats{Meta: map[string]fmt.Stringer{"status": intStringer(404)}}, "404"},
+	}
+	for _, c := range cases {
+		if got := metricsStatus(c.stats); got != c.want {
+			t.Errorf("%s: metricsStatus() = %q, want %q", c.name, got, c.want)
+		}
+	}
+}
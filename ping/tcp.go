@@ -0,0 +1,78 @@
+package ping
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"time"
+)
+
+func init() {
+	Register(TCP, newTCPPing)
+}
+
+// tcpDefaultTimeout is used when Option.Timeout is not set.
+const tcpDefaultTimeout = 5 * time.Second
+
+type tcpPing struct {
+	url *url.URL
+	op  *Option
+
+	// addrIndex round-robins across the resolved addresses of a
+	// multi-A/AAAA-record host, one position per probe.
+	addrIndex uint32
+}
+
+func newTCPPing(u *url.URL, op *Option) (Ping, error) {
+	return &tcpPing{url: u, op: op}, nil
+}
+
+// Ping dials p.url's host:port once and reports whether the TCP
+// handshake completed, tcping's original behavior.
+func (p *tcpPing) Ping(ctx context.Context) *Stats {
+	stats := &Stats{}
+
+	timeout := p.op.Timeout
+	if timeout <= 0 {
+		timeout = tcpDefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	resolver := p.op.Resolver
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+
+	lookupNetwork := p.op.Network
+	if lookupNetwork == "" {
+		lookupNetwork = "ip"
+	}
+
+	host := p.url.Hostname()
+	port := p.url.Port()
+
+	dnsStart := time.Now()
+	addrs, err := resolver.LookupIP(ctx, lookupNetwork, host)
+	stats.DNSDuration = time.Since(dnsStart)
+	if err != nil {
+		stats.Error = err
+		return stats
+	}
+	ip := pickAddr(addrs, &p.addrIndex, p.op.RandomizeAddress)
+	address := net.JoinHostPort(ip.String(), port)
+	stats.Address = address
+
+	dialer := &net.Dialer{Resolver: resolver}
+	start := time.Now()
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	stats.Duration = time.Since(start)
+	if err != nil {
+		stats.Error = err
+		return stats
+	}
+	defer conn.Close()
+
+	stats.Connected = true
+	return stats
+}
@@ -0,0 +1,50 @@
+package ping
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestTCPPingConnectsToListener(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen() error = %v", err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+
+	target, err := url.Parse("tcp://" + ln.Addr().String())
+	if err != nil {
+		t.Fatalf("url.Parse() error = %v", err)
+	}
+
+	prober, err := newTCPPing(target, &Option{Timeout: time.Second})
+	if err != nil {
+		t.Fatalf("newTCPPing() error = %v", err)
+	}
+
+	stats := prober.Ping(context.Background())
+	if stats.Error != nil {
+		t.Fatalf("Ping() error = %v", stats.Error)
+	}
+	if !stats.Connected {
+		t.Error("Ping() Connected = false, want true")
+	}
+}
+
+func TestTCPRegistered(t *testing.T) {
+	if Load(TCP) == nil {
+		t.Fatal("Load(TCP) = nil, want a registered factory")
+	}
+}